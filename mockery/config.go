@@ -0,0 +1,76 @@
+package mockery
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the declarative, on-disk equivalent of the mockery CLI flags. It
+// lets a repo list every interface it wants mocked once, in a
+// `.mockery.yaml` file, instead of shelling out to mockery per directory.
+//
+// Example:
+//
+//	packages:
+//	  github.com/17media/api/service/foo:
+//	    interfaces:
+//	      Store:
+//	        config:
+//	          dir: mocks
+//	          outpkg: mocks
+type Config struct {
+	Packages map[string]PackageConfig `yaml:"packages"`
+}
+
+// PackageConfig lists the interfaces to mock for a single import path.
+// Interfaces named explicitly under Interfaces are always mocked. Setting
+// All and/or Include/Exclude additionally selects interfaces the same way
+// an InterfaceSelector does, for packages where listing every name by hand
+// would be tedious; matches from that mode use Config as their MockConfig.
+// See InterfaceSelector for what Recursive controls.
+type PackageConfig struct {
+	Interfaces map[string]InterfaceConfig `yaml:"interfaces"`
+	All        bool                       `yaml:"all"`
+	Include    []string                   `yaml:"include"`
+	Exclude    []string                   `yaml:"exclude"`
+	Recursive  bool                       `yaml:"recursive"`
+	Config     MockConfig                 `yaml:"config"`
+}
+
+// InterfaceConfig carries the per-interface generation options. It is
+// wrapped in its own struct (rather than flattening MockConfig directly)
+// so future per-interface fields, such as embedding rules, have somewhere
+// to live without another level of YAML nesting.
+type InterfaceConfig struct {
+	Config MockConfig `yaml:"config"`
+}
+
+// MockConfig mirrors the mockery CLI flags that control where and how a
+// single mock is written.
+type MockConfig struct {
+	Dir       string `yaml:"dir"`
+	Filename  string `yaml:"filename"`
+	OutPkg    string `yaml:"outpkg"`
+	InPackage bool   `yaml:"inpackage"`
+	Note      string `yaml:"note"`
+	// Case controls the casing of derived mock filenames (see CaseStyle).
+	// Defaults to "snake".
+	Case CaseStyle `yaml:"case"`
+}
+
+// LoadConfig reads and parses a `.mockery.yaml` file.
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	cfg := &Config{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
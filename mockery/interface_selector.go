@@ -0,0 +1,139 @@
+package mockery
+
+import (
+	"fmt"
+	"go/types"
+	"os"
+	"path"
+	"unicode"
+	"unicode/utf8"
+)
+
+// InterfaceSelector decides which interfaces in a loaded package get
+// mocked. It replaces a single *regexp.Regexp Filter with the combination
+// of modes gqlgen and mockery-v2 users expect: explicit names, glob
+// include/exclude lists, an "all exported interfaces" switch, and a
+// recursive mode that pulls in embedded interfaces' promoted methods.
+type InterfaceSelector struct {
+	// Names, if non-empty, restricts matches to these exact interface
+	// names (still subject to Exclude).
+	Names []string
+	// Include is a list of glob patterns (path.Match syntax) matched
+	// against the interface name. An empty list matches everything.
+	Include []string
+	// Exclude is a list of glob patterns matched against the interface
+	// name; any match is dropped regardless of Names/Include/All.
+	Exclude []string
+	// All mocks every exported interface in the loaded package, subject to
+	// Include/Exclude.
+	All bool
+	// Recursive controls whether methods promoted from embedded interfaces
+	// are included in the generated mock. Parser.ParsePackage always
+	// resolves an interface's complete method set (via
+	// (*types.Interface).Complete), so when Recursive is false, Apply
+	// narrows iface.Type back down to just its explicitly declared methods
+	// before the interface is handed to the generator.
+	Recursive bool
+}
+
+// Match reports whether iface should be mocked under this selector. A nil
+// selector matches everything, preserving the behavior of an unset Filter.
+func (this *InterfaceSelector) Match(iface *Interface) bool {
+	if this == nil {
+		return true
+	}
+
+	if this.matchesExclude(iface.Name) {
+		return false
+	}
+
+	if len(this.Names) > 0 {
+		return containsName(this.Names, iface.Name)
+	}
+
+	if this.All {
+		return isExported(iface.Name) && this.matchesInclude(iface.Name)
+	}
+
+	return this.matchesInclude(iface.Name)
+}
+
+// Apply returns iface unchanged when this selector is nil, Recursive, or
+// iface has no resolved type (e.g. it came from a Parser that couldn't
+// type-check the package). Otherwise it returns a copy of iface whose Type
+// has been narrowed to just its explicitly declared methods, dropping any
+// methods promoted from embedded interfaces, so the generator emits only
+// what the interface itself declares.
+//
+// An interface composed entirely of embeds (e.g.
+// `type Store interface { Reader; Writer }`) has zero explicit methods;
+// narrowing that down would emit a mock satisfying none of Store's methods
+// instead of a merely incomplete one. Apply refuses to do that: it logs a
+// warning and keeps the full, embeds-included method set instead.
+func (this *InterfaceSelector) Apply(iface *Interface) *Interface {
+	if this == nil || this.Recursive || iface.Type == nil {
+		return iface
+	}
+
+	if iface.Type.NumExplicitMethods() == 0 {
+		fmt.Fprintf(os.Stderr, "InterfaceSelector: %s has no explicitly declared methods (composed entirely of embedded interfaces); keeping its full method set instead of generating an empty mock\n", iface.Name)
+		return iface
+	}
+
+	narrowed := *iface
+	narrowed.Type = explicitMethodSet(iface.Type)
+	return &narrowed
+}
+
+// explicitMethodSet builds a fresh, completed *types.Interface containing
+// only ifaceType's explicitly declared methods, with no embedded interfaces,
+// so its method set excludes anything promoted from an embed.
+func explicitMethodSet(ifaceType *types.Interface) *types.Interface {
+	methods := make([]*types.Func, ifaceType.NumExplicitMethods())
+	for i := range methods {
+		methods[i] = ifaceType.ExplicitMethod(i)
+	}
+
+	explicit := types.NewInterfaceType(methods, nil)
+	explicit.Complete()
+	return explicit
+}
+
+func (this *InterfaceSelector) matchesInclude(name string) bool {
+	if len(this.Include) == 0 {
+		return true
+	}
+	return matchesAny(this.Include, name)
+}
+
+func (this *InterfaceSelector) matchesExclude(name string) bool {
+	return matchesAny(this.Exclude, name)
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		ok, err := path.Match(pattern, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid glob pattern %q: %v\n", pattern, err)
+			continue
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isExported(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
@@ -0,0 +1,114 @@
+package mockery
+
+import (
+	"go/types"
+	"testing"
+)
+
+func TestInterfaceSelectorMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector *InterfaceSelector
+		iface    string
+		want     bool
+	}{
+		{"nil selector matches everything", nil, "Foo", true},
+		{"explicit name match", &InterfaceSelector{Names: []string{"Foo", "Bar"}}, "Foo", true},
+		{"explicit name miss", &InterfaceSelector{Names: []string{"Foo", "Bar"}}, "Baz", false},
+		{"explicit name overridden by exclude", &InterfaceSelector{Names: []string{"Foo"}, Exclude: []string{"Foo"}}, "Foo", false},
+		{"all matches exported", &InterfaceSelector{All: true}, "Foo", true},
+		{"all skips unexported", &InterfaceSelector{All: true}, "foo", false},
+		{"all respects include glob", &InterfaceSelector{All: true, Include: []string{"Foo*"}}, "FooStore", true},
+		{"all respects include glob miss", &InterfaceSelector{All: true, Include: []string{"Foo*"}}, "BarStore", false},
+		{"all respects exclude glob", &InterfaceSelector{All: true, Exclude: []string{"*Internal"}}, "FooInternal", false},
+		{"bare include glob without all", &InterfaceSelector{Include: []string{"Foo*"}}, "FooStore", true},
+		{"empty include matches everything", &InterfaceSelector{}, "AnythingGoes", true},
+		{"invalid glob pattern doesn't match, doesn't panic", &InterfaceSelector{Include: []string{"[unterminated"}}, "Foo", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iface := &Interface{Name: tt.iface}
+			if got := tt.selector.Match(iface); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.iface, got, tt.want)
+			}
+		})
+	}
+}
+
+// newInterfaceType builds a *types.Interface with the given explicit method
+// names and embedded interfaces, already Complete()'d, for use as test
+// fixtures.
+func newInterfaceType(methodNames []string, embeds ...*types.Interface) *types.Interface {
+	pkg := types.NewPackage("example.com/foo", "foo")
+	methods := make([]*types.Func, len(methodNames))
+	for i, name := range methodNames {
+		sig := types.NewSignatureType(nil, nil, nil, nil, nil, false)
+		methods[i] = types.NewFunc(0, pkg, name, sig)
+	}
+
+	embedded := make([]types.Type, len(embeds))
+	for i, e := range embeds {
+		embedded[i] = e
+	}
+
+	iface := types.NewInterfaceType(methods, embedded)
+	iface.Complete()
+	return iface
+}
+
+func TestInterfaceSelectorApply(t *testing.T) {
+	t.Run("nil selector returns iface unchanged", func(t *testing.T) {
+		ifaceType := newInterfaceType([]string{"Read"})
+		iface := &Interface{Name: "Reader", Type: ifaceType}
+
+		got := (*InterfaceSelector)(nil).Apply(iface)
+		if got.Type != ifaceType {
+			t.Errorf("Apply returned a different Type for a nil selector")
+		}
+	})
+
+	t.Run("recursive selector returns iface unchanged", func(t *testing.T) {
+		ifaceType := newInterfaceType([]string{"Read"})
+		iface := &Interface{Name: "Reader", Type: ifaceType}
+
+		got := (&InterfaceSelector{Recursive: true}).Apply(iface)
+		if got.Type != ifaceType {
+			t.Errorf("Apply narrowed Type despite Recursive being true")
+		}
+	})
+
+	t.Run("non-recursive narrows to explicit methods only", func(t *testing.T) {
+		embedded := newInterfaceType([]string{"Read"})
+		ifaceType := newInterfaceType([]string{"Close"}, embedded)
+		if ifaceType.NumMethods() != 2 {
+			t.Fatalf("fixture setup: want 2 promoted+explicit methods, got %d", ifaceType.NumMethods())
+		}
+
+		iface := &Interface{Name: "ReadCloser", Type: ifaceType}
+		got := (&InterfaceSelector{}).Apply(iface)
+
+		if got.Type.NumMethods() != 1 {
+			t.Fatalf("Apply narrowed to %d methods, want 1 (explicit only)", got.Type.NumMethods())
+		}
+		if got.Type.Method(0).Name() != "Close" {
+			t.Errorf("Apply kept %q, want the explicit method Close", got.Type.Method(0).Name())
+		}
+	})
+
+	t.Run("embed-only interface keeps its full method set instead of narrowing to zero", func(t *testing.T) {
+		reader := newInterfaceType([]string{"Read"})
+		writer := newInterfaceType([]string{"Write"})
+		ifaceType := newInterfaceType(nil, reader, writer)
+		if ifaceType.NumExplicitMethods() != 0 {
+			t.Fatalf("fixture setup: want 0 explicit methods, got %d", ifaceType.NumExplicitMethods())
+		}
+
+		iface := &Interface{Name: "ReadWriter", Type: ifaceType}
+		got := (&InterfaceSelector{}).Apply(iface)
+
+		if got.Type.NumMethods() != 2 {
+			t.Errorf("Apply narrowed an embed-only interface down to %d methods, want the full 2", got.Type.NumMethods())
+		}
+	})
+}
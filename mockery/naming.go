@@ -0,0 +1,153 @@
+package mockery
+
+import (
+	"strings"
+	"unicode"
+)
+
+// CaseStyle controls how FileOutputStreamProvider (directly, or via
+// GeneratorVisitor.Case) derives a mock's filename from an interface name.
+// Mock identifiers are not derived with it.
+type CaseStyle string
+
+const (
+	CaseSnake      CaseStyle = "snake"
+	CaseCamel      CaseStyle = "camel"
+	CaseUnderscore CaseStyle = "underscore"
+)
+
+// golintInitialisms lists the identifiers golint/revive treat as a single
+// word rather than a run of capitalized letters, borrowed from moq. Without
+// this, "HTTPClient" splits letter-by-letter into "h_t_t_p_client" instead
+// of "http_client".
+var golintInitialisms = map[string]bool{
+	"ACL":   true,
+	"API":   true,
+	"ASCII": true,
+	"CPU":   true,
+	"CSS":   true,
+	"DNS":   true,
+	"EOF":   true,
+	"GUID":  true,
+	"HTML":  true,
+	"HTTP":  true,
+	"HTTPS": true,
+	"ID":    true,
+	"IP":    true,
+	"JSON":  true,
+	"LHS":   true,
+	"QPS":   true,
+	"RAM":   true,
+	"RHS":   true,
+	"RPC":   true,
+	"SLA":   true,
+	"SMTP":  true,
+	"SQL":   true,
+	"SSH":   true,
+	"TCP":   true,
+	"TLS":   true,
+	"TTL":   true,
+	"UDP":   true,
+	"UI":    true,
+	"UID":   true,
+	"UUID":  true,
+	"URI":   true,
+	"URL":   true,
+	"UTF8":  true,
+	"VM":    true,
+	"XML":   true,
+	"XMPP":  true,
+	"XSRF":  true,
+	"XSS":   true,
+}
+
+// splitWords splits an identifier into its constituent words, keeping any
+// known golint initialism (see golintInitialisms) together instead of
+// splitting it letter-by-letter. Runs of 2-5 uppercase runes are checked
+// against the initialism list, longest match first.
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if !unicode.IsUpper(r) {
+			current = append(current, r)
+			continue
+		}
+
+		if initialism, n := matchInitialism(runes[i:]); initialism != "" {
+			flush()
+			words = append(words, initialism)
+			i += n - 1
+			continue
+		}
+
+		// Standard camel-case split: an uppercase rune starts a new word
+		// unless it's continuing a run already begun by an initialism
+		// check above (which we would have consumed already).
+		if len(current) > 0 {
+			flush()
+		}
+		current = append(current, r)
+	}
+	flush()
+
+	return words
+}
+
+// matchInitialism looks for the longest known initialism (2-5 runes) at the
+// start of runes, returning it and its length, or ("", 0) if none matches.
+func matchInitialism(runes []rune) (string, int) {
+	maxLen := 5
+	if len(runes) < maxLen {
+		maxLen = len(runes)
+	}
+	for n := maxLen; n >= 2; n-- {
+		candidate := string(runes[:n])
+		if golintInitialisms[candidate] {
+			return candidate, n
+		}
+	}
+	return "", 0
+}
+
+// toSnake converts an identifier such as "HTTPClient" to "http_client",
+// preserving golint initialisms as single words.
+func toSnake(name string) string {
+	words := splitWords(name)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, "_")
+}
+
+// toLower lowercases name outright, used for the CaseUnderscore style where
+// words are joined but not separated (e.g. mocks package-relative paths).
+func toLower(name string) string {
+	return strings.ToLower(name)
+}
+
+// applyCaseStyle renders name according to style, defaulting to CaseSnake
+// (the style mockery has always used for filenames) when style is empty.
+func applyCaseStyle(name string, style CaseStyle) string {
+	switch style {
+	case CaseCamel:
+		return name
+	case CaseUnderscore:
+		return toLower(name)
+	case CaseSnake, "":
+		return toSnake(name)
+	default:
+		return toSnake(name)
+	}
+}
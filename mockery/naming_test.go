@@ -0,0 +1,105 @@
+package mockery
+
+import "testing"
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"HTTPClient", []string{"HTTP", "Client"}},
+		{"UUIDStore", []string{"UUID", "Store"}},
+		{"Client", []string{"Client"}},
+		{"client", []string{"client"}},
+		{"ID", []string{"ID"}},
+		{"UserID", []string{"User", "ID"}},
+		{"APIClientID", []string{"API", "Client", "ID"}},
+		{"XMLHTTPRequest", []string{"XML", "HTTP", "Request"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitWords(tt.name)
+			if !equalStrings(got, tt.want) {
+				t.Errorf("splitWords(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchInitialism(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantLen int
+	}{
+		{"HTTPClient", "HTTP", 4},
+		{"UUIDStore", "UUID", 4},
+		{"IDFoo", "ID", 2},
+		{"Client", "", 0},
+		{"XY", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, n := matchInitialism([]rune(tt.in))
+			if got != tt.want || n != tt.wantLen {
+				t.Errorf("matchInitialism(%q) = (%q, %d), want (%q, %d)", tt.in, got, n, tt.want, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestToSnake(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"HTTPClient", "http_client"},
+		{"UUIDStore", "uuid_store"},
+		{"Client", "client"},
+		{"UserID", "user_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := toSnake(tt.in); got != tt.want {
+				t.Errorf("toSnake(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyCaseStyle(t *testing.T) {
+	tests := []struct {
+		name  string
+		style CaseStyle
+		want  string
+	}{
+		{"HTTPClient", CaseSnake, "http_client"},
+		{"HTTPClient", "", "http_client"},
+		{"HTTPClient", CaseCamel, "HTTPClient"},
+		{"HTTPClient", CaseUnderscore, "httpclient"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.style)+"/"+tt.name, func(t *testing.T) {
+			if got := applyCaseStyle(tt.name, tt.style); got != tt.want {
+				t.Errorf("applyCaseStyle(%q, %q) = %q, want %q", tt.name, tt.style, got, tt.want)
+			}
+		})
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package mockery
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// OutputStreamProvider resolves where a generated mock should be written.
+type OutputStreamProvider interface {
+	GetWriter(iface *Interface) (io.Writer, error, func())
+}
+
+// FileOutputStreamProvider writes each mock to its own file on disk.
+type FileOutputStreamProvider struct {
+	// BaseDir is the directory mocks are written to when InPackage is false.
+	// Defaults to "mocks".
+	BaseDir string
+	// InPackage writes the mock alongside the interface it mocks instead of
+	// under BaseDir.
+	InPackage bool
+	// Filename overrides the derived filename entirely. Leave empty to
+	// derive one from the interface name and Case.
+	Filename string
+	// Case controls how the derived filename is cased; see CaseStyle.
+	// Defaults to CaseSnake.
+	Case CaseStyle
+}
+
+func (this *FileOutputStreamProvider) GetWriter(iface *Interface) (io.Writer, error, func()) {
+	dir := this.BaseDir
+	if this.InPackage {
+		dir = filepath.Dir(iface.FileName)
+	} else if dir == "" {
+		dir = "mocks"
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err, func() {}
+	}
+
+	filename := this.Filename
+	if filename == "" {
+		filename = "mock_" + applyCaseStyle(iface.Name, this.Case) + ".go"
+	}
+
+	f, err := os.Create(filepath.Join(dir, filename))
+	if err != nil {
+		return nil, err, func() {}
+	}
+
+	return f, nil, func() { f.Close() }
+}
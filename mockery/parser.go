@@ -0,0 +1,116 @@
+package mockery
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Interface describes a single interface discovered by the type checker.
+type Interface struct {
+	Name     string
+	FileName string
+	Pkg      *types.Package
+	// Type is the interface's complete method set, including any methods
+	// promoted from embedded interfaces, as ParsePackage resolves it.
+	// InterfaceSelector.Apply may return a copy narrowed to the explicitly
+	// declared methods.
+	Type *types.Interface
+}
+
+// Parser accumulates the interfaces and DI register entry found across the
+// packages handed to it via ParsePackage. Discovery is driven entirely by
+// the type checker's object graph (pkg.Types.Scope()), not by walking the
+// AST and guessing which declarations look like interfaces.
+type Parser struct {
+	buildTags     []string
+	interfaces    []*Interface
+	registerEntry *parserEntry
+}
+
+// parserEntry is the single file Parser found a Get-prefixed function (such
+// as a `func GetXxx() *Xxx` constructor) in, used by
+// GeneratorVisitor.GenerateMockRegister.
+type parserEntry struct {
+	fileName string
+	pkgPath  string
+	syntax   *ast.File
+}
+
+func NewParser(buildTags []string) *Parser {
+	return &Parser{buildTags: buildTags}
+}
+
+// ParsePackage consumes an already-loaded, type-checked *packages.Package.
+// Interface discovery walks the package's type scope and asks the type
+// checker whether each named type's underlying type is a *types.Interface,
+// so embedded interfaces, dot-imports and build-tag-gated files are all
+// handled exactly as the go command itself resolves them.
+func (this *Parser) ParsePackage(pkg *packages.Package) error {
+	if pkg.Types == nil || pkg.TypesInfo == nil {
+		return fmt.Errorf("package %s was not type-checked (missing NeedTypes/NeedTypesInfo)", pkg.PkgPath)
+	}
+
+	scope := pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+
+		ifaceType, ok := typeName.Type().Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+		ifaceType.Complete()
+
+		this.interfaces = append(this.interfaces, &Interface{
+			Name:     typeName.Name(),
+			FileName: pkg.Fset.Position(typeName.Pos()).Filename,
+			Pkg:      pkg.Types,
+			Type:     ifaceType,
+		})
+	}
+
+	for _, file := range pkg.Syntax {
+		this.findRegisterEntry(file, pkg.Fset.Position(file.Pos()).Filename, pkg.PkgPath)
+	}
+
+	return nil
+}
+
+// findRegisterEntry records the first file seen across all parsed packages
+// that declares a function or method whose name starts with "Get" (such as
+// a `func GetXxx() *Xxx` constructor; the signature is not checked), which
+// GenerateMockRegister inspects to build the DI registration file.
+// pkgPath is pkg.PkgPath as resolved by the packages loader, so
+// GenerateMockRegister doesn't have to reverse-engineer an import path out
+// of fileName and $GOPATH/src.
+func (this *Parser) findRegisterEntry(file *ast.File, fileName, pkgPath string) {
+	if this.registerEntry != nil {
+		return
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !strings.HasPrefix(fn.Name.Name, "Get") {
+			continue
+		}
+		this.registerEntry = &parserEntry{fileName: fileName, pkgPath: pkgPath, syntax: file}
+		return
+	}
+}
+
+// Load is a no-op now that parsing happens eagerly in ParsePackage; it is
+// kept so Walker's call site doesn't need to change based on how Parser
+// does its work.
+func (this *Parser) Load() error {
+	return nil
+}
+
+func (this *Parser) Interfaces() []*Interface {
+	return this.interfaces
+}
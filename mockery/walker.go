@@ -1,25 +1,59 @@
 package mockery
 
 import (
+	"bytes"
 	"fmt"
 	"go/ast"
-	"go/build"
 	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"text/template"
+
+	"golang.org/x/tools/go/packages"
 )
 
+// packagesLoadMode is the set of information the loader needs from each
+// package: enough to resolve interface method sets via the type checker
+// instead of re-parsing and guessing from the AST.
+const packagesLoadMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+	packages.NeedTypes | packages.NeedTypesInfo | packages.NeedDeps
+
 type Walker struct {
 	BaseDir   string
 	Recursive bool
+	// Patterns is a list of package patterns such as "./...",
+	// "github.com/foo/bar/..." or a bare import path. When set, it takes
+	// precedence over BaseDir/Recursive. BaseDir/Recursive remain supported
+	// as one of several input modes and are translated into an equivalent
+	// pattern.
+	Patterns []string
+	// Filter is retained for backward compatibility with callers built
+	// against a single name regex. When Selector is set, it takes
+	// precedence.
 	Filter    *regexp.Regexp
+	Selector  *InterfaceSelector
 	LimitOne  bool
 	BuildTags []string
 }
 
+// matches reports whether iface should be mocked, preferring Selector over
+// the legacy Filter regex when both happen to be set.
+func (this *Walker) matches(iface *Interface) bool {
+	if this.Selector != nil {
+		return this.Selector.Match(iface)
+	}
+	if this.Filter != nil {
+		return this.Filter.MatchString(iface.Name)
+	}
+	return true
+}
+
 type WalkerVisitor interface {
 	VisitWalk(*Interface) error
 	GenerateMockRegister(*parserEntry) error
@@ -27,9 +61,24 @@ type WalkerVisitor interface {
 
 func (this *Walker) Walk(visitor WalkerVisitor) (generated bool) {
 	parser := NewParser(this.BuildTags)
-	this.doWalk(parser, this.BaseDir, visitor)
 
-	err := parser.Load()
+	pkgs, err := this.loadPackages()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading packages: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, pkg := range pkgs {
+		for _, pkgErr := range pkg.Errors {
+			fmt.Fprintln(os.Stderr, "Error loading package:", pkgErr)
+		}
+		if err := parser.ParsePackage(pkg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing package %s: %v\n", pkg.PkgPath, err)
+			continue
+		}
+	}
+
+	err = parser.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error walking: %v\n", err)
 		os.Exit(1)
@@ -40,9 +89,10 @@ func (this *Walker) Walk(visitor WalkerVisitor) (generated bool) {
 	}
 
 	for _, iface := range parser.Interfaces() {
-		if !this.Filter.MatchString(iface.Name) {
+		if !this.matches(iface) {
 			continue
 		}
+		iface = this.Selector.Apply(iface)
 		err := visitor.VisitWalk(iface)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error walking %s: %s\n", iface.Name, err)
@@ -57,113 +107,327 @@ func (this *Walker) Walk(visitor WalkerVisitor) (generated bool) {
 	return
 }
 
-func (this *Walker) doWalk(p *Parser, dir string, visitor WalkerVisitor) (generated bool) {
-	files, err := ioutil.ReadDir(dir)
+// WalkFromConfig generates mocks for every interface listed in cfg, rather
+// than walking a single BaseDir and filtering by regex. Each configured
+// package is loaded independently so its MockConfig (dir/filename/outpkg/
+// inpackage/note) can be applied per interface.
+func (this *Walker) WalkFromConfig(cfg *Config) (generated bool) {
+	for pkgPath, pkgCfg := range cfg.Packages {
+		if this.walkPackageConfig(pkgPath, pkgCfg) {
+			generated = true
+		}
+	}
+	return
+}
+
+func (this *Walker) walkPackageConfig(pkgPath string, pkgCfg PackageConfig) (generated bool) {
+	loadCfg := &packages.Config{Mode: packagesLoadMode}
+	if len(this.BuildTags) > 0 {
+		loadCfg.BuildFlags = []string{"-tags=" + strings.Join(this.BuildTags, ",")}
+	}
+
+	pkgs, err := packages.Load(loadCfg, pkgPath)
 	if err != nil {
-		return
+		fmt.Fprintf(os.Stderr, "Error loading package %s: %v\n", pkgPath, err)
+		os.Exit(1)
 	}
 
-	for _, file := range files {
-		if strings.HasPrefix(file.Name(), ".") || strings.HasPrefix(file.Name(), "_") {
+	parser := NewParser(this.BuildTags)
+	for _, pkg := range pkgs {
+		for _, pkgErr := range pkg.Errors {
+			fmt.Fprintln(os.Stderr, "Error loading package:", pkgErr)
+		}
+		if err := parser.ParsePackage(pkg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing package %s: %v\n", pkgPath, err)
 			continue
 		}
+	}
 
-		path := filepath.Join(dir, file.Name())
+	if err := parser.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error walking %s: %v\n", pkgPath, err)
+		os.Exit(1)
+	}
 
-		if file.IsDir() {
-			if this.Recursive {
-				generated = this.doWalk(p, path, visitor) || generated
-				if generated && this.LimitOne {
-					return
-				}
-			}
+	byName := make(map[string]*Interface, len(parser.Interfaces()))
+	for _, iface := range parser.Interfaces() {
+		byName[iface.Name] = iface
+	}
+
+	for name, ifaceCfg := range pkgCfg.Interfaces {
+		iface, ok := byName[name]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Interface %s not found in %s\n", name, pkgPath)
 			continue
 		}
 
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
-			continue
+		visitor := visitorFromMockConfig(ifaceCfg.Config)
+		if err := visitor.VisitWalk(iface); err != nil {
+			fmt.Fprintf(os.Stderr, "Error walking %s: %s\n", iface.Name, err)
+			os.Exit(1)
 		}
+		generated = true
+	}
 
-		err = p.Parse(path)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "Error parsing file: ", err)
-			continue
+	if pkgCfg.All || len(pkgCfg.Include) > 0 || len(pkgCfg.Exclude) > 0 {
+		selector := &InterfaceSelector{
+			Include:   pkgCfg.Include,
+			Exclude:   pkgCfg.Exclude,
+			All:       pkgCfg.All,
+			Recursive: pkgCfg.Recursive,
+		}
+		visitor := visitorFromMockConfig(pkgCfg.Config)
+
+		for name, iface := range byName {
+			if _, explicit := pkgCfg.Interfaces[name]; explicit {
+				continue
+			}
+			if !selector.Match(iface) {
+				continue
+			}
+			iface = selector.Apply(iface)
+			if err := visitor.VisitWalk(iface); err != nil {
+				fmt.Fprintf(os.Stderr, "Error walking %s: %s\n", iface.Name, err)
+				os.Exit(1)
+			}
+			generated = true
 		}
 	}
 
 	return
 }
 
-type GeneratorVisitor struct {
-	InPackage bool
-	Note      string
-	Osp       OutputStreamProvider
-	// The name of the output package, if InPackage is false (defaults to "mocks")
-	PackageName string
+// visitorFromMockConfig builds a GeneratorVisitor from a single interface's
+// YAML config, mirroring the options the CLI exposes as flags.
+func visitorFromMockConfig(mc MockConfig) *GeneratorVisitor {
+	return &GeneratorVisitor{
+		InPackage:   mc.InPackage,
+		Note:        mc.Note,
+		PackageName: mc.OutPkg,
+		Case:        mc.Case,
+		Osp: &FileOutputStreamProvider{
+			BaseDir:   mc.Dir,
+			InPackage: mc.InPackage,
+			Filename:  mc.Filename,
+			Case:      mc.Case,
+		},
+	}
 }
 
-func (this *GeneratorVisitor) GenerateMockRegister(entry *parserEntry) error {
+// loadPackages expands this Walker's patterns (à la gotool.ImportPaths) and
+// loads them once with go/packages, so build tags, generated files, vendored
+// deps and modules are all resolved the same way the go command itself sees
+// them.
+func (this *Walker) loadPackages() ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packagesLoadMode,
+	}
+	if len(this.BuildTags) > 0 {
+		cfg.BuildFlags = []string{"-tags=" + strings.Join(this.BuildTags, ",")}
+	}
+
+	return packages.Load(cfg, this.patterns()...)
+}
 
-	template := `
+// patterns returns the package patterns to load, translating the legacy
+// BaseDir/Recursive fields into an equivalent pattern when Patterns isn't
+// set explicitly.
+func (this *Walker) patterns() []string {
+	if len(this.Patterns) > 0 {
+		return this.Patterns
+	}
+
+	dir := dirPattern(this.BaseDir)
+	if this.Recursive {
+		return []string{dir + "/..."}
+	}
+	return []string{dir}
+}
+
+// dirPattern normalizes dir into a pattern go/packages is guaranteed to
+// treat as a filesystem directory rather than an import path: per
+// `go help packages`, that's only true when the pattern is rooted, exactly
+// "." or starts with "./" or "../"; such patterns are returned unchanged.
+// Any other BaseDir (e.g. "internal/foo", which
+// is how most existing callers set it) is prefixed with "./" so it keeps
+// meaning "this directory" instead of silently becoming an import path.
+func dirPattern(dir string) string {
+	if dir == "" || dir == "." {
+		return "."
+	}
+	if filepath.IsAbs(dir) || strings.HasPrefix(dir, "./") || strings.HasPrefix(dir, "../") {
+		return dir
+	}
+	return "./" + dir
+}
+
+// defaultRegisterTemplate reproduces the register file this package has
+// always emitted (same imports and RegisterMock function; only the stray
+// tabs on its blank lines are gone), so leaving
+// GeneratorVisitor.RegisterTemplate unset is backward compatible with every
+// existing caller.
+const defaultRegisterTemplate = `
 package mocks
 
 import (
-	"%s"
-	
+	"{{.ImportPath}}"
+
 	"github.com/17media/api/setup/dimanager"
 )
-	
-func RegisterMock(m *dimanager.Manager) *%s {
-	mockObj := &%s{}
-	m.ProvideMock(func() %s.%s { return mockObj }, "%s")
+
+func RegisterMock(m *dimanager.Manager) *{{.InterfaceName}} {
+	mockObj := &{{.InterfaceName}}{}
+	m.ProvideMock(func() {{.Package}}.{{.InterfaceName}} { return mockObj }, "{{.DepName}}")
 	return mockObj
 }
 `
-	//fmt.Printf("%#v", entry)
-	//srcPath := filepath.Join(build.Default.GOPATH, "src")
-	srcPath := strings.Join([]string{build.Default.GOPATH, "src", ""}, "/")
-	//fmt.Println(srcPath)
-	importpkg := strings.Replace(filepath.Dir(entry.fileName), srcPath, "", 1)
-	//fmt.Println(importpkg)
-	pkg := filepath.Base(importpkg)
-
-	interfaceName := ""
-	depName := ""
-
-	//fset := token.NewFileSet()
+
+// defaultDIMarkerSelector is the selector name (e.g. `di.In`) a struct field
+// must embed for GenerateMockRegister to treat its sibling field's tag as
+// the dependency name, matching the shape uber/fx's fx.In marker uses.
+const defaultDIMarkerSelector = "In"
+
+// defaultDepTagKey is the struct tag key read off the field alongside the DI
+// marker to obtain the dependency name, e.g. `name:"fooStore"`.
+const defaultDepTagKey = "name"
+
+// RegisterTemplateData is the data passed to GeneratorVisitor.RegisterTemplate.
+type RegisterTemplateData struct {
+	ImportPath    string
+	Package       string
+	InterfaceName string
+	DepName       string
+	OutputPath    string
+}
+
+type GeneratorVisitor struct {
+	InPackage bool
+	Note      string
+	Osp       OutputStreamProvider
+	// The name of the output package, if InPackage is false (defaults to "mocks")
+	PackageName string
+
+	// RegisterTemplate renders the DI registration file emitted by
+	// GenerateMockRegister. It defaults to defaultRegisterTemplate, which
+	// wires into github.com/17media/api/setup/dimanager; set it to plug in
+	// wire, fx, dig or a bespoke container instead.
+	RegisterTemplate *template.Template
+	// RegisterOutputPath is where the rendered template is written.
+	// Defaults to "./mocks/register.go".
+	RegisterOutputPath string
+	// DIMarkerSelector is the selector name (see defaultDIMarkerSelector)
+	// that marks a struct field as the DI parameter block to inspect.
+	DIMarkerSelector string
+	// DepTagKey is the struct tag key (see defaultDepTagKey) read to
+	// recover the registered dependency's name.
+	DepTagKey string
+
+	// Case controls how derived mock filenames are cased, by handing it to a
+	// FileOutputStreamProvider that has no Case of its own. Mock identifiers
+	// are not affected. Defaults to CaseSnake. See CaseStyle.
+	Case CaseStyle
+}
+
+func (this *GeneratorVisitor) registerTemplate() *template.Template {
+	if this.RegisterTemplate != nil {
+		return this.RegisterTemplate
+	}
+	return template.Must(template.New("register").Parse(defaultRegisterTemplate))
+}
+
+func (this *GeneratorVisitor) registerOutputPath() string {
+	if this.RegisterOutputPath != "" {
+		return this.RegisterOutputPath
+	}
+	return "./mocks/register.go"
+}
+
+// registerReturnName recovers the type name GetXxx returns, whether it's
+// declared as `func GetXxx() Xxx` (*ast.Ident) or, per this package's own
+// documented shape, `func GetXxx() *Xxx` (*ast.StarExpr wrapping an
+// *ast.Ident). Returns ok=false for any other result shape (no results,
+// qualified/generic types, etc.) instead of panicking.
+func registerReturnName(fn *ast.FuncDecl) (string, bool) {
+	if fn.Type.Results == nil || len(fn.Type.Results.List) == 0 {
+		return "", false
+	}
+
+	result := fn.Type.Results.List[0].Type
+	if star, ok := result.(*ast.StarExpr); ok {
+		result = star.X
+	}
+
+	ident, ok := result.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	return ident.Name, true
+}
+
+// extractRegisterNames walks entry's AST to recover the interface name
+// registered by a `func GetXxx() *Xxx` constructor and the DI dependency
+// name declared on the struct field immediately following the DIMarkerSelector
+// field (e.g. `di.In` in a params struct), read off DepTagKey.
+func (this *GeneratorVisitor) extractRegisterNames(entry *parserEntry) (interfaceName, depName string) {
+	marker := this.DIMarkerSelector
+	if marker == "" {
+		marker = defaultDIMarkerSelector
+	}
+	tagKey := this.DepTagKey
+	if tagKey == "" {
+		tagKey = defaultDepTagKey
+	}
+
 	ast.Inspect(entry.syntax, func(node ast.Node) bool {
 		switch nt := node.(type) {
 		case *ast.FuncDecl:
 			if strings.HasPrefix(nt.Name.Name, "Get") {
-				//ast.Print(fset, nt)
-				starReturn := nt.Type.Results.List[0].Type.(*ast.Ident)
-				interfaceName = starReturn.Name
+				if name, ok := registerReturnName(nt); ok {
+					interfaceName = name
+				}
 			}
 		case *ast.FieldList:
-			//ast.Print(fset, nt)
 			if len(nt.List) == 2 {
 				first, firstOK := nt.List[0].Type.(*ast.SelectorExpr)
 				if !firstOK {
 					return true
 				}
-				if first.Sel.Name != "In" {
+				if first.Sel.Name != marker {
 					return true
 				}
-				depName = nt.List[1].Tag.Value
-				depName = strings.Split(depName, "\"")[1]
+				tagValue, err := strconv.Unquote(nt.List[1].Tag.Value)
+				if err != nil {
+					return true
+				}
+				depName = reflect.StructTag(tagValue).Get(tagKey)
 			}
 		}
 		return true
 	})
-	//fmt.Print(this.PackageName)
-	formatCode := fmt.Sprintf(template, importpkg, interfaceName, interfaceName, pkg, interfaceName, depName)
-	fmt.Println(formatCode)
-	err := ioutil.WriteFile("./mocks/register.go", []byte(formatCode), 0644)
-	if err != nil {
+
+	return interfaceName, depName
+}
+
+func (this *GeneratorVisitor) GenerateMockRegister(entry *parserEntry) error {
+	importpkg := entry.pkgPath
+	pkg := path.Base(importpkg)
+
+	interfaceName, depName := this.extractRegisterNames(entry)
+
+	outputPath := this.registerOutputPath()
+	data := RegisterTemplateData{
+		ImportPath:    importpkg,
+		Package:       pkg,
+		InterfaceName: interfaceName,
+		DepName:       depName,
+		OutputPath:    outputPath,
+	}
+
+	var buf bytes.Buffer
+	if err := this.registerTemplate().Execute(&buf, data); err != nil {
 		return err
 	}
 
-	return nil
+	return ioutil.WriteFile(outputPath, buf.Bytes(), 0644)
 }
 
 func (this *GeneratorVisitor) VisitWalk(iface *Interface) error {
@@ -183,6 +447,13 @@ func (this *GeneratorVisitor) VisitWalk(iface *Interface) error {
 		pkg = this.PackageName
 	}
 
+	// Give a FileOutputStreamProvider our Case if it wasn't configured with
+	// its own, so the visitor's Case decides the mock's filename (e.g.
+	// mock_http_client.go for HTTPClient under CaseSnake).
+	if fosp, ok := this.Osp.(*FileOutputStreamProvider); ok && fosp.Case == "" {
+		fosp.Case = this.Case
+	}
+
 	out, err, closer := this.Osp.GetWriter(iface)
 	if err != nil {
 		fmt.Printf("Unable to get writer for %s: %s", iface.Name, err)